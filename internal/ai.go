@@ -0,0 +1,114 @@
+package internal
+
+import "math"
+
+// Speeds, in pixels/second, for each AI difficulty tier.
+const (
+	aiSpeedEasy       = 160.0
+	aiSpeedNormal     = 240.0
+	aiSpeedHard       = 300.0
+	aiSpeedImpossible = 420.0
+)
+
+// updateAI picks the right-hand paddle's input and speed for the current
+// difficulty. It returns how far (in pixels) the paddle is from where the
+// tier wants it, plus the tier's speed; the caller turns the difference into
+// an Up/Down input so stepPhysics has one code path regardless of who
+// drives that paddle.
+func (g *Game) updateAI() (upDown, speed float64) {
+	speed = aiSpeedForDifficulty(g.cfg.Difficulty)
+	switch g.cfg.Difficulty {
+	case DifficultyEasy:
+		return g.aiEasy(), speed
+	case DifficultyHard:
+		return g.aiHard(), speed
+	case DifficultyImpossible:
+		return g.aiImpossible(), speed
+	default:
+		return g.aiNormal(), speed
+	}
+}
+
+// aiSpeedForDifficulty returns the paddle speed, in pixels/second, for the
+// given tier. It's also what a replay recording of an AI match stores in
+// its header, so playback moves the AI paddle exactly as far per tick as
+// the original run did.
+func aiSpeedForDifficulty(d Difficulty) float64 {
+	switch d {
+	case DifficultyEasy:
+		return aiSpeedEasy
+	case DifficultyHard:
+		return aiSpeedHard
+	case DifficultyImpossible:
+		return aiSpeedImpossible
+	default:
+		return aiSpeedNormal
+	}
+}
+
+// aiNormal just tracks the ball's current Y, same as the very first AI this
+// game had.
+func (g *Game) aiNormal() float64 {
+	target := g.ball.Y + BaseBallSize/2 - BasePaddleHeight/2
+	return target - g.ai.Y
+}
+
+// aiEasy tracks the ball with a wide reaction-noise band so it reacts late
+// and imprecisely, on top of already being capped to aiSpeedEasy. The noise
+// is drawn from aiRandSrc, not randSrc: that keeps AI noise off the same
+// stream resetBall/randomizeBackground draw from, so a replay recording
+// (which never calls updateAI on playback) doesn't shift the physics RNG
+// out of sync with what was recorded.
+func (g *Game) aiEasy() float64 {
+	noise := (g.aiRandSrc.Float64() - 0.5) * 160
+	target := g.ball.Y + BaseBallSize/2 - BasePaddleHeight/2 + noise
+	return target - g.ai.Y
+}
+
+// aiHard predicts where the ball will cross the AI's X by integrating its
+// velocity forward and reflecting off the top/bottom walls, then moves
+// toward that with a small human-like error that scales with how far off
+// it's aiming. See aiEasy for why the error term comes from aiRandSrc.
+func (g *Game) aiHard() float64 {
+	predictedY := predictBallY(g.ball, g.ai.X)
+	aim := predictedY + BaseBallSize/2 - BasePaddleHeight/2
+	errorMargin := (g.aiRandSrc.Float64() - 0.5) * math.Abs(aim-g.ai.Y) / 10
+	target := aim + errorMargin
+	return target - g.ai.Y
+}
+
+// aiImpossible is the same prediction as aiHard with the error term
+// removed, paired with the fastest paddle speed.
+func (g *Game) aiImpossible() float64 {
+	predictedY := predictBallY(g.ball, g.ai.X)
+	target := predictedY + BaseBallSize/2 - BasePaddleHeight/2
+	return target - g.ai.Y
+}
+
+// predictBallY integrates the ball's velocity forward to estimate its Y
+// position by the time it reaches x, reflecting off the top/bottom walls
+// the same way a light ray bounces between two mirrors.
+func predictBallY(b Ball, x float64) float64 {
+	if b.VX <= 0 {
+		return b.Y
+	}
+	dist := x - b.X
+	if dist <= 0 {
+		return b.Y
+	}
+	t := dist / b.VX
+	y := b.Y + b.VY*t
+
+	// Ball.Update reflects b.Y within [0, BaseHeight-BaseBallSize], not
+	// [0, BaseHeight], so fold the prediction into that same range or it
+	// overshoots near the walls.
+	span := float64(BaseHeight - BaseBallSize)
+	y = math.Mod(y, 2*span)
+	if y < 0 {
+		y += 2 * span
+	}
+	if y > span {
+		y = 2*span - y
+	}
+	return y
+}
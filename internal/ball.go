@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// BaseBallSize is expressed in the BaseWidth x BaseHeight virtual
+	// resolution; Draw scales it to the actual window size.
+	// BaseBallSpeed is in pixels per second, so motion stays the same
+	// regardless of the physics tick rate.
+	BaseBallSize  = 12
+	BaseBallSpeed = 300.0
+)
+
+// Ball is the puck. It only knows how to move itself within a rectangle and
+// draw itself; paddle collisions are resolved by Game since that's the only
+// place that knows about both the ball and the paddles.
+type Ball struct {
+	X, Y   float64
+	VX, VY float64
+}
+
+// Update moves the ball by its current velocity (in pixels/second) over dt
+// seconds and reflects it off the top/bottom edges of bounds.
+func (b *Ball) Update(bounds image.Rectangle, dt float64) {
+	b.X += b.VX * dt
+	b.Y += b.VY * dt
+
+	if b.Y <= float64(bounds.Min.Y) {
+		b.Y = float64(bounds.Min.Y)
+		b.VY = -b.VY
+	}
+	if b.Y+BaseBallSize >= float64(bounds.Max.Y) {
+		b.Y = float64(bounds.Max.Y) - BaseBallSize
+		b.VY = -b.VY
+	}
+}
+
+// Draw renders the ball, mapping its base-resolution position/size to
+// screen space via scale and offsetX/offsetY.
+func (b *Ball) Draw(img *ebiten.Image, scale, offsetX, offsetY float64) {
+	ebitenutil.DrawRect(img,
+		offsetX+b.X*scale, offsetY+b.Y*scale,
+		BaseBallSize*scale, BaseBallSize*scale,
+		color.RGBA{255, 160, 0, 255})
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Difficulty selects one of Game.updateAI's behaviors.
+type Difficulty string
+
+const (
+	DifficultyEasy       Difficulty = "easy"
+	DifficultyNormal     Difficulty = "normal"
+	DifficultyHard       Difficulty = "hard"
+	DifficultyImpossible Difficulty = "impossible"
+)
+
+// Valid reports whether d is one of the known difficulty tiers.
+func (d Difficulty) Valid() bool {
+	switch d {
+	case DifficultyEasy, DifficultyNormal, DifficultyHard, DifficultyImpossible:
+		return true
+	}
+	return false
+}
+
+// Config holds everything about a match that used to be hardcoded: window
+// size, match length, ball physics, and AI difficulty. CLI flags override
+// ~/.config/ping_pong/config.toml, and the merged result is written back
+// so the next run without flags picks up what was last used.
+type Config struct {
+	ScreenWidth   int
+	ScreenHeight  int
+	TargetScore   int
+	BallSpeed     float64
+	SpeedupFactor float64
+	Difficulty    Difficulty
+}
+
+// DefaultConfig returns the values the game shipped with before flags and
+// a config file existed.
+func DefaultConfig() Config {
+	return Config{
+		ScreenWidth:   BaseWidth,
+		ScreenHeight:  BaseHeight,
+		TargetScore:   7,
+		BallSpeed:     BaseBallSpeed,
+		SpeedupFactor: 1.05,
+		Difficulty:    DifficultyNormal,
+	}
+}
+
+// hash returns a checksum of the fields that affect simulation outcome, so
+// a replay reader can tell whether it's about to play frames recorded
+// under a different Config (e.g. a different target score or ball speed)
+// before the mismatch shows up as a desync partway through the match.
+func (cfg Config) hash() uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%g|%g|%s", cfg.TargetScore, cfg.BallSpeed, cfg.SpeedupFactor, cfg.Difficulty)
+	return h.Sum64()
+}
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ping_pong", "config.toml"), nil
+}
+
+// LoadConfig reads ~/.config/ping_pong/config.toml over top of
+// DefaultConfig. A missing file (the common case on first run) is not an
+// error; LoadConfig just returns the defaults.
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		// A value that fails to parse leaves the corresponding default in
+		// place rather than zeroing it out; e.g. a corrupt screen_width
+		// line shouldn't turn into ebiten.SetWindowSize(0, ...).
+		switch key {
+		case "screen_width":
+			if v, err := strconv.Atoi(value); err == nil {
+				cfg.ScreenWidth = v
+			}
+		case "screen_height":
+			if v, err := strconv.Atoi(value); err == nil {
+				cfg.ScreenHeight = v
+			}
+		case "target_score":
+			if v, err := strconv.Atoi(value); err == nil {
+				cfg.TargetScore = v
+			}
+		case "ball_speed":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				cfg.BallSpeed = v
+			}
+		case "speedup_factor":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				cfg.SpeedupFactor = v
+			}
+		case "difficulty":
+			cfg.Difficulty = Difficulty(value)
+		}
+	}
+	return cfg, scanner.Err()
+}
+
+// SaveConfig persists cfg to ~/.config/ping_pong/config.toml so the next
+// run without flags remembers it.
+func SaveConfig(cfg Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "screen_width = %d\n", cfg.ScreenWidth)
+	fmt.Fprintf(&b, "screen_height = %d\n", cfg.ScreenHeight)
+	fmt.Fprintf(&b, "target_score = %d\n", cfg.TargetScore)
+	fmt.Fprintf(&b, "ball_speed = %g\n", cfg.BallSpeed)
+	fmt.Fprintf(&b, "speedup_factor = %g\n", cfg.SpeedupFactor)
+	fmt.Fprintf(&b, "difficulty = %q\n", string(cfg.Difficulty))
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
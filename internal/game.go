@@ -0,0 +1,662 @@
+// Package internal composes the Ball, Paddle and Game types into the
+// Pong simulation. Everything here operates in a BaseWidth x BaseHeight
+// virtual resolution; Game.Draw scales that to whatever the actual window
+// size is, which is what makes the game resolution-independent.
+package internal
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+
+	"pingpong/internal/replay"
+	"pingpong/netplay"
+)
+
+// BaseWidth and BaseHeight are the virtual resolution all game logic runs
+// in. The actual window can be any size; Draw scales and letterboxes the
+// base resolution to fit it.
+const (
+	BaseWidth  = 800
+	BaseHeight = 600
+)
+
+const (
+	// paddleDeflection is per second: it turns a hit's offset from the
+	// paddle's center (pixels) into the ball's new VY (pixels/second).
+	paddleDeflection = 7.2
+
+	// physicsHz is the fixed rate the simulation steps at, independent of
+	// Ebiten's TPS or the display's refresh rate. Update accumulates real
+	// elapsed time and drains it in physicsDT chunks so ball/paddle speed
+	// doesn't change if either of those rates change.
+	physicsHz      = 120.0
+	physicsDT      = 1.0 / physicsHz
+	maxAccumulated = 0.25 // cap to avoid a spiral of death after a stall
+)
+
+// Mode selects who drives the right-hand paddle: the built-in AI, or a
+// remote peer reached as host or joiner.
+type Mode int
+
+const (
+	ModeMenu Mode = iota
+	ModeLocal
+	ModeHost
+	ModeJoin
+	ModeReplay
+)
+
+// defaultListenAddr and defaultPeerAddr are placeholders for the host/join
+// handshake until CLI flags exist to override them.
+const (
+	defaultListenAddr = ":7878"
+	defaultPeerAddr   = "127.0.0.1:7878"
+)
+
+// Game composes a Ball and two Paddles and implements ebiten.Game.
+type Game struct {
+	cfg   Config
+	state GameState
+	mode  Mode
+
+	// screenWidth/screenHeight track the actual window size reported by
+	// Layout, used only to scale drawing; simulation always runs in the
+	// BaseWidth x BaseHeight space.
+	screenWidth, screenHeight int
+
+	player      *Paddle
+	ai          *Paddle
+	ball        Ball
+	playerScore int
+	aiScore     int
+	randSrc     *pcgRand
+	seed        uint64
+	// aiRandSrc feeds aiEasy/aiHard's noise, kept separate from randSrc so
+	// AI decisions don't perturb the physics RNG stream that resetBall and
+	// randomizeBackground draw from (see aiEasy's doc comment).
+	aiRandSrc *pcgRand
+	bgColor   color.RGBA
+
+	// recordPath is where to write a replay of the next match started,
+	// set via SetRecordPath before the menu is reached. Empty means don't
+	// record.
+	recordPath string
+
+	// Recording/playback. replayOut is non-nil while a match is being
+	// recorded to disk (any mode); replayIn is non-nil in ModeReplay,
+	// where it replaces both the keyboard and the AI/netplay peer as the
+	// source of input.
+	replayOut     *replay.Writer
+	replayOutFile *os.File
+	replayIn      *replay.Reader
+	replayInFile  *os.File
+
+	// replayRemoteSpeed is the opponent paddle speed read from the replay
+	// header being played back (see replay.Header.RemoteSpeed), used in
+	// place of BasePaddleSpeed/aiSpeed so ModeReplay moves that paddle
+	// exactly as far per tick as it did while recording.
+	replayRemoteSpeed float64
+
+	// Networked play. net is nil in ModeLocal. frame counts simulation
+	// ticks since the handshake and is the shared clock rollback is
+	// keyed on; predictedRemote is what we assume the peer is doing
+	// until their real input for that frame arrives.
+	net             *netplay.Session
+	frame           uint64
+	predictedRemote netplay.Input
+	waitingForPeer  bool
+
+	// Fixed-timestep accumulator. lastUpdateTime/accumulator drive how
+	// many physicsDT steps to run on a given Update call; prevBall/
+	// prevPlayerY/prevAIY hold positions from just before the most
+	// recent step so Draw can interpolate using renderAlpha.
+	lastUpdateTime time.Time
+	accumulator    float64
+	renderAlpha    float64
+	prevBall       Ball
+	prevPlayerY    float64
+	prevAIY        float64
+
+	// Draw-on-demand. dirty is set by markDirty whenever something Draw
+	// would need to repaint (a paddle/ball moved, a score changed, the
+	// background or state changed, the window resized) and cleared once
+	// Draw has run. drawFrame carries those requests to the goroutine
+	// started by startDrawScheduler, which coalesces them and is what
+	// actually saves GPU work: paired with SetScreenClearedEveryFrame
+	// (false), Ebiten skips re-rendering a frame Draw left untouched.
+	dirty     bool
+	drawFrame chan bool
+}
+
+// NewGame returns a Game sitting at the mode-select menu, configured by cfg.
+func NewGame(cfg Config) *Game {
+	g := &Game{
+		cfg:          cfg,
+		state:        StateMenu,
+		mode:         ModeMenu,
+		screenWidth:  cfg.ScreenWidth,
+		screenHeight: cfg.ScreenHeight,
+		dirty:        true, // draw the first frame
+		drawFrame:    make(chan bool, 1),
+	}
+	g.startDrawScheduler()
+	return g
+}
+
+// markDirty flags that Draw has something new to paint and wakes the draw
+// scheduler. The non-blocking send is what lets several mutations in the
+// same Update (ball moved and score changed, say) collapse into one
+// scheduled frame instead of queuing up.
+func (g *Game) markDirty() {
+	g.dirty = true
+	select {
+	case g.drawFrame <- true:
+	default:
+	}
+}
+
+// startDrawScheduler launches the goroutine that turns markDirty's redraw
+// requests into ebiten.ScheduleFrame calls. It ticks at physicsHz, which
+// caps how often it asks for a frame to at most the simulation rate
+// (comfortably above any real display's refresh rate), so a burst of
+// dirtying within one tick still only schedules a single redraw.
+func (g *Game) startDrawScheduler() {
+	ticker := time.NewTicker(time.Second / physicsHz)
+	go func() {
+		pending := false
+		for {
+			select {
+			case <-g.drawFrame:
+				pending = true
+			case <-ticker.C:
+				if pending {
+					ebiten.ScheduleFrame()
+					pending = false
+				}
+			}
+		}
+	}()
+}
+
+func (g *Game) bounds() image.Rectangle {
+	return image.Rect(0, 0, BaseWidth, BaseHeight)
+}
+
+// startLocal begins a single-player match against the built-in AI.
+func (g *Game) startLocal() {
+	g.mode = ModeLocal
+	g.state = StatePlaying
+	g.seed = uint64(time.Now().UnixNano())
+	g.randSrc = newPCGRand(g.seed, 0)
+	g.aiRandSrc = newPCGRand(g.seed, 1)
+	g.resetMatch()
+	g.startRecordingIfRequested()
+}
+
+// SetRecordPath arranges for the next match started from the menu to be
+// recorded to path as it's played, in the format StartReplay reads back.
+func (g *Game) SetRecordPath(path string) {
+	g.recordPath = path
+}
+
+func (g *Game) startRecordingIfRequested() {
+	if g.recordPath == "" {
+		return
+	}
+	path := g.recordPath
+	g.recordPath = "" // only the next match is recorded
+	if err := g.StartRecording(path); err != nil {
+		fmt.Fprintf(os.Stderr, "ping_pong: could not start replay recording: %v\n", err)
+	}
+}
+
+// startNetwork performs the handshake for the given role and begins a
+// networked match once both peers have agreed on a seed and start frame.
+func (g *Game) startNetwork(role netplay.Role) error {
+	sess, err := netplay.Handshake(role, defaultListenAddr, defaultPeerAddr, uint64(time.Now().UnixNano()))
+	if err != nil {
+		return err
+	}
+	g.net = sess
+	g.frame = sess.StartFrame()
+	g.seed = sess.Seed()
+	g.randSrc = newPCGRand(g.seed, 0)
+	if role == netplay.RoleHost {
+		g.mode = ModeHost
+	} else {
+		g.mode = ModeJoin
+	}
+	g.state = StatePlaying
+	g.resetMatch()
+	g.startRecordingIfRequested()
+	return nil
+}
+
+func (g *Game) resetMatch() {
+	g.player = newLeftPaddle(g.bounds())
+	g.ai = newRightPaddle(g.bounds())
+	g.playerScore = 0
+	g.aiScore = 0
+	g.lastUpdateTime = time.Now()
+	g.accumulator = 0
+	g.randomizeBackground()
+	g.resetBall(true)
+	g.prevBall = g.ball
+	g.prevPlayerY, g.prevAIY = g.player.Y, g.ai.Y
+}
+
+func (g *Game) randomizeBackground() {
+	// Pick a random color while avoiding extremely bright backgrounds
+	rr := uint8(10 + g.randSrc.Intn(230))
+	gg := uint8(10 + g.randSrc.Intn(230))
+	bb := uint8(10 + g.randSrc.Intn(230))
+	// adjust if too bright for readable UI
+	lum := 0.299*float64(rr) + 0.587*float64(gg) + 0.114*float64(bb)
+	if lum > 180 {
+		factor := 180.0 / lum
+		rr = uint8(float64(rr) * factor)
+		gg = uint8(float64(gg) * factor)
+		bb = uint8(float64(bb) * factor)
+	}
+	g.bgColor = color.RGBA{rr, gg, bb, 255}
+}
+
+func (g *Game) resetBall(toPlayer bool) {
+	g.ball.X = BaseWidth/2 - BaseBallSize/2
+	g.ball.Y = BaseHeight/2 - BaseBallSize/2
+
+	angle := (g.randSrc.Float64()*math.Pi/3 - math.Pi/6) // -30 to +30 degrees
+	speed := g.cfg.BallSpeed
+	if toPlayer {
+		angle += math.Pi // invert direction towards player
+	}
+	g.ball.VX = speed * math.Cos(angle)
+	g.ball.VY = speed * math.Sin(angle)
+	// small random tweak so not exactly horizontal sometimes
+	if math.Abs(g.ball.VY) < 0.5 {
+		g.ball.VY = g.ball.VY + 0.5*(g.randSrc.Float64()-0.5)
+	}
+}
+
+func (g *Game) Update() error {
+	in := readInputState()
+
+	switch g.state {
+	case StateMenu:
+		return g.updateMenu(in)
+	case StatePaused:
+		if in.PauseJustPressed {
+			g.state = StatePlaying
+			g.markDirty()
+		}
+		return nil
+	case StateGameOver:
+		if in.ConfirmJustPressed {
+			g.closeReplay()
+			g.state = StateMenu
+			g.mode = ModeMenu
+			g.markDirty()
+		}
+		return nil
+	}
+
+	if in.PauseJustPressed {
+		g.state = StatePaused
+		g.markDirty()
+		return nil
+	}
+
+	return g.advancePhysics(in)
+}
+
+// advancePhysics drains real elapsed time in fixed physicsDT chunks so ball
+// and paddle motion is independent of both Ebiten's TPS and the display's
+// refresh rate. renderAlpha is left pointing at how far into the next,
+// not-yet-simulated chunk we are, for Draw to interpolate with.
+func (g *Game) advancePhysics(in InputState) error {
+	localIn := netplay.Input{Up: in.Up, Down: in.Down}
+
+	now := time.Now()
+	elapsed := now.Sub(g.lastUpdateTime).Seconds()
+	g.lastUpdateTime = now
+	if elapsed > maxAccumulated {
+		elapsed = maxAccumulated
+	}
+	g.accumulator += elapsed
+
+	for g.accumulator >= physicsDT {
+		g.prevBall = g.ball
+		g.prevPlayerY, g.prevAIY = g.player.Y, g.ai.Y
+
+		switch {
+		case g.mode == ModeReplay:
+			if err := g.stepReplay(); err != nil {
+				return err
+			}
+		case g.net != nil:
+			if err := g.stepNetworked(localIn); err != nil {
+				return err
+			}
+		default:
+			diff, aiSpeed := g.updateAI()
+			aiInput := netplay.Input{Up: diff < -1, Down: diff > 1}
+			g.recordFrame(localIn, aiInput)
+			g.stepPhysics(localIn, aiInput, aiSpeed)
+		}
+
+		g.markDirty()
+		g.accumulator -= physicsDT
+
+		// A state transition (pause, game over) mid-drain means the rest
+		// of this Update call's accumulated time waits for next tick.
+		if g.state != StatePlaying {
+			break
+		}
+	}
+
+	g.renderAlpha = g.accumulator / physicsDT
+	return nil
+}
+
+func (g *Game) updateMenu(in InputState) error {
+	switch {
+	case in.LocalJustPressed:
+		g.startLocal()
+		g.markDirty()
+	case in.HostJustPressed:
+		if err := g.startNetwork(netplay.RoleHost); err != nil {
+			return err
+		}
+		g.markDirty()
+	case in.JoinJustPressed:
+		if err := g.startNetwork(netplay.RoleJoin); err != nil {
+			return err
+		}
+		g.markDirty()
+	}
+	return nil
+}
+
+// stepNetworked advances one fixed-timestep frame of a networked match: it
+// sends this tick's local input, absorbs whatever remote input has
+// arrived, and rewinds/resimulates if a past prediction turns out to have
+// been wrong.
+func (g *Game) stepNetworked(localIn netplay.Input) error {
+	g.net.SaveState(g.frame, g.snapshotState(g.frame))
+
+	if err := g.net.SendLocalInput(g.frame, localIn); err != nil {
+		return err
+	}
+	if err := g.net.PollRemoteInput(); err != nil {
+		return err
+	}
+
+	remoteIn, ok := g.net.RemoteInput(g.frame)
+	if !ok {
+		// Peer hasn't told us what they're doing yet: predict they kept
+		// doing whatever they did last frame and carry on. We'll correct
+		// this via rollback once the real input shows up.
+		g.waitingForPeer = true
+		remoteIn = g.predictedRemote
+	} else {
+		g.waitingForPeer = false
+		g.predictedRemote = remoteIn
+	}
+	g.net.SaveAppliedRemote(g.frame, remoteIn)
+
+	g.recordFrame(localIn, remoteIn)
+	g.stepPhysics(localIn, remoteIn, BasePaddleSpeed)
+	g.frame++
+
+	g.reconcileRollback()
+	return nil
+}
+
+// reconcileRollback checks whether any frame in the rollback window was
+// simulated with a remote input that turned out, now that the real value
+// has arrived, to be a misprediction. If so it restores the snapshot from
+// just before that frame and resimulates forward to the present using the
+// now-known inputs.
+func (g *Game) reconcileRollback() {
+	if g.frame == 0 {
+		return
+	}
+	oldest := uint64(0)
+	if g.frame > 8 {
+		oldest = g.frame - 8
+	}
+	for f := oldest; f < g.frame; f++ {
+		confirmed, ok := g.net.RemoteInput(f)
+		if !ok {
+			continue
+		}
+		applied, ok := g.net.AppliedRemoteInput(f)
+		if !ok || applied == confirmed {
+			continue
+		}
+		state, ok := g.net.RewindTo(f)
+		if !ok {
+			continue
+		}
+		// Misprediction confirmed at frame f: restore the simulation as
+		// it was just before f ran, then resimulate every subsequent
+		// frame with whatever input we now know (real where available,
+		// confirmed-at-f otherwise), recording what actually got applied
+		// so a later pass can tell if that guess was wrong too.
+		g.restoreState(state)
+		for rf := f; rf < g.frame; rf++ {
+			lin, _ := g.net.LocalInput(rf)
+			rin, known := g.net.RemoteInput(rf)
+			if !known {
+				rin = confirmed
+			}
+			g.net.SaveAppliedRemote(rf, rin)
+			g.net.SaveState(rf, g.snapshotState(rf))
+			g.stepPhysics(lin, rin, BasePaddleSpeed)
+		}
+		return
+	}
+}
+
+func (g *Game) snapshotState(frame uint64) netplay.GameState {
+	return netplay.GameState{
+		Frame:       frame,
+		PlayerY:     g.player.Y,
+		AIY:         g.ai.Y,
+		BallX:       g.ball.X,
+		BallY:       g.ball.Y,
+		BallVX:      g.ball.VX,
+		BallVY:      g.ball.VY,
+		PlayerScore: g.playerScore,
+		AIScore:     g.aiScore,
+		RNGState:    g.randSrc.state,
+	}
+}
+
+func (g *Game) restoreState(s netplay.GameState) {
+	g.player.Y = s.PlayerY
+	g.ai.Y = s.AIY
+	g.ball.X = s.BallX
+	g.ball.Y = s.BallY
+	g.ball.VX = s.BallVX
+	g.ball.VY = s.BallVY
+	g.playerScore = s.PlayerScore
+	g.aiScore = s.AIScore
+	g.randSrc.state = s.RNGState
+}
+
+// stepPhysics advances the simulation by exactly one tick given this
+// frame's local and remote (AI or peer) input and the speed the remote
+// paddle moves at. It touches nothing but game state, which is what makes
+// it safe to call repeatedly during a rollback resimulation.
+func (g *Game) stepPhysics(local, remote netplay.Input, remoteSpeed float64) {
+	bounds := g.bounds()
+
+	velocity := 0.0
+	if local.Up {
+		velocity -= BasePaddleSpeed
+	}
+	if local.Down {
+		velocity += BasePaddleSpeed
+	}
+	g.player.Move(velocity, physicsDT, bounds)
+
+	velocity = 0.0
+	if remote.Up {
+		velocity -= remoteSpeed
+	}
+	if remote.Down {
+		velocity += remoteSpeed
+	}
+	g.ai.Move(velocity, physicsDT, bounds)
+
+	g.ball.Update(bounds, physicsDT)
+
+	// Paddle collisions
+	if rectsCollide(g.ball.X, g.ball.Y, BaseBallSize, BaseBallSize, g.player.X, g.player.Y, BasePaddleWidth, BasePaddleHeight) {
+		g.ball.X = g.player.X + BasePaddleWidth
+		g.ball.VX = math.Abs(g.ball.VX) // go right
+		offset := (g.ball.Y + BaseBallSize/2) - (g.player.Y + BasePaddleHeight/2)
+		g.ball.VY = offset * paddleDeflection
+		g.ball.VX *= g.cfg.SpeedupFactor
+		g.ball.VY *= g.cfg.SpeedupFactor
+		g.randomizeBackground()
+	}
+	if rectsCollide(g.ball.X, g.ball.Y, BaseBallSize, BaseBallSize, g.ai.X, g.ai.Y, BasePaddleWidth, BasePaddleHeight) {
+		g.ball.X = g.ai.X - BaseBallSize
+		g.ball.VX = -math.Abs(g.ball.VX) // go left
+		offset := (g.ball.Y + BaseBallSize/2) - (g.ai.Y + BasePaddleHeight/2)
+		g.ball.VY = offset * paddleDeflection
+		g.ball.VX *= g.cfg.SpeedupFactor
+		g.ball.VY *= g.cfg.SpeedupFactor
+		g.randomizeBackground()
+	}
+
+	// Scoring: left out -> AI scores, right out -> player scores
+	if g.ball.X+BaseBallSize < 0 {
+		g.aiScore++
+		g.resetBall(true)
+	}
+	if g.ball.X > BaseWidth {
+		g.playerScore++
+		g.resetBall(false)
+	}
+
+	// First to TargetScore wins the match; hand off to the game-over
+	// screen instead of silently resetting so the result is legible
+	// before a new match starts.
+	if g.playerScore >= g.cfg.TargetScore || g.aiScore >= g.cfg.TargetScore {
+		g.state = StateGameOver
+	}
+}
+
+func rectsCollide(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
+	return x1 < x2+w2 && x1+w1 > x2 && y1 < y2+h2 && y1+h1 > y2
+}
+
+func lerp(a, b, alpha float64) float64 {
+	return a + (b-a)*alpha
+}
+
+// drawScale returns the uniform scale factor and letterbox offset used to
+// map the BaseWidth x BaseHeight simulation onto the actual window.
+func (g *Game) drawScale() (scale, offsetX, offsetY float64) {
+	scale = math.Min(float64(g.screenWidth)/BaseWidth, float64(g.screenHeight)/BaseHeight)
+	offsetX = (float64(g.screenWidth) - BaseWidth*scale) / 2
+	offsetY = (float64(g.screenHeight) - BaseHeight*scale) / 2
+	return
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	// SetScreenClearedEveryFrame(false) means Ebiten leaves the screen as
+	// Draw last left it; skipping repaint entirely while nothing changed
+	// is what cuts GPU work on the menu/pause/idle screens this game
+	// mostly sits on.
+	if !g.dirty {
+		return
+	}
+	g.dirty = false
+
+	if g.state == StateMenu {
+		g.drawMenu(screen)
+		return
+	}
+
+	scale, offsetX, offsetY := g.drawScale()
+
+	ebitenutil.DrawRect(screen, 0, 0, float64(g.screenWidth), float64(g.screenHeight), color.Black)
+	ebitenutil.DrawRect(screen, offsetX, offsetY, BaseWidth*scale, BaseHeight*scale, g.bgColor)
+
+	// Center dashed line
+	for y := 0; y < BaseHeight; y += 20 {
+		ebitenutil.DrawRect(screen, offsetX+(BaseWidth/2-2)*scale, offsetY+float64(y)*scale, 4*scale, 12*scale, color.RGBA{200, 200, 200, 50})
+	}
+
+	// Interpolate between the last two physics steps using renderAlpha so
+	// motion stays smooth even when the display refreshes faster than
+	// physicsHz.
+	alpha := g.renderAlpha
+	interpPlayer := Paddle{X: g.player.X, Y: lerp(g.prevPlayerY, g.player.Y, alpha)}
+	interpAI := Paddle{X: g.ai.X, Y: lerp(g.prevAIY, g.ai.Y, alpha)}
+	interpBall := Ball{
+		X: lerp(g.prevBall.X, g.ball.X, alpha),
+		Y: lerp(g.prevBall.Y, g.ball.Y, alpha),
+	}
+	interpPlayer.Draw(screen, scale, offsetX, offsetY)
+	interpAI.Draw(screen, scale, offsetX, offsetY)
+	interpBall.Draw(screen, scale, offsetX, offsetY)
+
+	scoreText := fmt.Sprintf("%d    %d", g.playerScore, g.aiScore)
+	text.Draw(screen, scoreText, basicfont.Face7x13, int(offsetX+(BaseWidth/2-20)*scale), int(offsetY+30*scale), color.White)
+
+	text.Draw(screen, "W/S or ↑/↓ — move | P — pause", basicfont.Face7x13, 10, g.screenHeight-10, color.RGBA{200, 200, 200, 200})
+
+	if g.waitingForPeer {
+		text.Draw(screen, "waiting for peer...", basicfont.Face7x13, g.screenWidth/2-60, g.screenHeight/2-40, color.RGBA{255, 80, 80, 255})
+	}
+	if g.mode == ModeReplay {
+		text.Draw(screen, "REPLAY", basicfont.Face7x13, 10, 20, color.RGBA{255, 220, 80, 255})
+	} else if g.replayOut != nil {
+		text.Draw(screen, "REC", basicfont.Face7x13, 10, 20, color.RGBA{255, 60, 60, 255})
+	}
+
+	switch g.state {
+	case StatePaused:
+		text.Draw(screen, "PAUSED — press P to resume", basicfont.Face7x13, g.screenWidth/2-80, g.screenHeight/2, color.White)
+	case StateGameOver:
+		winner := "Player"
+		if g.aiScore > g.playerScore {
+			winner = "Opponent"
+		}
+		text.Draw(screen, winner+" wins! Press Enter for menu", basicfont.Face7x13, g.screenWidth/2-100, g.screenHeight/2, color.White)
+	}
+}
+
+func (g *Game) drawMenu(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, float64(g.screenWidth), float64(g.screenHeight), color.RGBA{20, 20, 30, 255})
+	cx, cy := g.screenWidth/2, g.screenHeight/2
+	text.Draw(screen, "PONG", basicfont.Face7x13, cx-20, cy-60, color.White)
+	text.Draw(screen, "L — local vs AI", basicfont.Face7x13, cx-70, cy-20, color.White)
+	text.Draw(screen, "H — host network game", basicfont.Face7x13, cx-70, cy, color.White)
+	text.Draw(screen, "J — join network game", basicfont.Face7x13, cx-70, cy+20, color.White)
+}
+
+// Layout reports the actual window size back to Ebiten so the game renders
+// at native resolution instead of a fixed 800x600; Draw takes care of
+// scaling the simulation to fit.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if outsideWidth != g.screenWidth || outsideHeight != g.screenHeight {
+		g.screenWidth, g.screenHeight = outsideWidth, outsideHeight
+		g.markDirty()
+	}
+	return outsideWidth, outsideHeight
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputState is one tick's snapshot of keyboard intent, split into held
+// keys (sampled every tick, e.g. paddle movement) and edge-triggered keys
+// (true only on the tick a key transitions from up to down). Driving state
+// transitions off the edge-triggered fields, rather than polling
+// IsKeyPressed and debouncing with a sleep, keeps Update free of anything
+// that would stall Ebiten's frame pacing or rollback resimulation.
+type InputState struct {
+	Up, Down bool
+
+	PauseJustPressed   bool
+	ConfirmJustPressed bool
+	LocalJustPressed   bool
+	HostJustPressed    bool
+	JoinJustPressed    bool
+}
+
+func readInputState() InputState {
+	return InputState{
+		Up:   ebiten.IsKeyPressed(ebiten.KeyW) || ebiten.IsKeyPressed(ebiten.KeyArrowUp),
+		Down: ebiten.IsKeyPressed(ebiten.KeyS) || ebiten.IsKeyPressed(ebiten.KeyArrowDown),
+
+		PauseJustPressed:   inpututil.IsKeyJustPressed(ebiten.KeyP),
+		ConfirmJustPressed: inpututil.IsKeyJustPressed(ebiten.KeyEnter),
+		LocalJustPressed:   inpututil.IsKeyJustPressed(ebiten.KeyL),
+		HostJustPressed:    inpututil.IsKeyJustPressed(ebiten.KeyH),
+		JoinJustPressed:    inpututil.IsKeyJustPressed(ebiten.KeyJ),
+	}
+}
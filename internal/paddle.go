@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// BasePaddleWidth and BasePaddleHeight are expressed in the
+	// BaseWidth x BaseHeight virtual resolution; Draw scales them to the
+	// actual window size. BasePaddleSpeed is in pixels per second, so
+	// motion stays the same regardless of the physics tick rate.
+	BasePaddleWidth  = 12
+	BasePaddleHeight = 90
+	BasePaddleSpeed  = 360.0
+
+	paddleMargin = 20
+)
+
+// Paddle is one side's bat. isControlledByPlayer marks whether this
+// instance's keyboard drives it directly; the other paddle is driven by the
+// AI or, in a networked match, by the peer's input.
+type Paddle struct {
+	X, Y                 float64
+	isControlledByPlayer bool
+}
+
+// newLeftPaddle places a player-controlled paddle near the left edge of
+// bounds, vertically centered.
+func newLeftPaddle(bounds image.Rectangle) *Paddle {
+	return &Paddle{
+		X:                    float64(bounds.Min.X) + paddleMargin,
+		Y:                    float64(bounds.Min.Y+bounds.Max.Y)/2 - BasePaddleHeight/2,
+		isControlledByPlayer: true,
+	}
+}
+
+// newRightPaddle places an AI/remote-controlled paddle near the right edge
+// of bounds, vertically centered.
+func newRightPaddle(bounds image.Rectangle) *Paddle {
+	return &Paddle{
+		X:                    float64(bounds.Max.X) - paddleMargin - BasePaddleWidth,
+		Y:                    float64(bounds.Min.Y+bounds.Max.Y)/2 - BasePaddleHeight/2,
+		isControlledByPlayer: false,
+	}
+}
+
+// Move shifts the paddle vertically at velocity pixels/second over dt
+// seconds and clamps it to bounds.
+func (p *Paddle) Move(velocity, dt float64, bounds image.Rectangle) {
+	p.Y += velocity * dt
+	if p.Y < float64(bounds.Min.Y) {
+		p.Y = float64(bounds.Min.Y)
+	}
+	if max := float64(bounds.Max.Y) - BasePaddleHeight; p.Y > max {
+		p.Y = max
+	}
+}
+
+// Draw renders the paddle, mapping its base-resolution position/size to
+// screen space via scale and offsetX/offsetY.
+func (p *Paddle) Draw(img *ebiten.Image, scale, offsetX, offsetY float64) {
+	ebitenutil.DrawRect(img,
+		offsetX+p.X*scale, offsetY+p.Y*scale,
+		BasePaddleWidth*scale, BasePaddleHeight*scale,
+		color.White)
+}
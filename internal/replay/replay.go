@@ -0,0 +1,147 @@
+// Package replay records and plays back the exact sequence of inputs that
+// drove a match, so given the same seed and config it reproduces the match
+// byte-for-byte through the deterministic fixed-timestep simulation in
+// package internal. It knows nothing about Game; it only reads and writes
+// frames of Input.
+package replay
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// formatVersion is bumped whenever Header or the frame encoding changes in
+// an incompatible way.
+const formatVersion = 1
+
+// Header is the fixed-size preamble written once at the start of a replay
+// file. ConfigHash lets a reader detect that it's about to play frames
+// recorded under a different Config (different target score or ball speed
+// would desync the moment physics runs), and TickRate records physicsHz so
+// a future change to the simulation rate doesn't silently misplay old
+// files.
+type Header struct {
+	Version    uint32
+	Seed       uint64
+	ConfigHash uint64
+	TickRate   uint32
+
+	// RemoteSpeed is the pixels/second speed the opponent paddle moved at
+	// while recording: the AI's difficulty-tier speed for a local match,
+	// or BasePaddleSpeed for a networked one. Input alone doesn't
+	// determine how far a tick's Up/Down moves the paddle, so without
+	// this a replay of an AI match would move the paddle at the wrong
+	// speed and desync within a rally.
+	RemoteSpeed float64
+}
+
+// Input is one paddle's intent for a single tick, mirroring netplay.Input's
+// shape so callers can convert between the two with a struct literal.
+type Input struct {
+	Up, Down bool
+}
+
+// Frame is one tick of recorded input: the local player's and the
+// opponent's (AI or remote peer's, depending on how the match was played).
+type Frame struct {
+	Local, Remote Input
+}
+
+// Bit layout of a frame's flag byte. Every frame is exactly one byte. A side
+// whose input is the same as the previous frame's sets its "repeat" bit
+// instead of its Up/Down bits; this does not make the frame any smaller.
+const (
+	flagLocalRepeat = 1 << iota
+	flagLocalUp
+	flagLocalDown
+	flagRemoteRepeat
+	flagRemoteUp
+	flagRemoteDown
+)
+
+// Writer appends delta-encoded Frames to an underlying file after a fixed
+// Header.
+type Writer struct {
+	w    io.Writer
+	prev Frame
+	have bool
+}
+
+// NewWriter writes h to w and returns a Writer ready to accept frames.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	h.Version = formatVersion
+	if err := binary.Write(w, binary.LittleEndian, h); err != nil {
+		return nil, fmt.Errorf("replay: write header: %w", err)
+	}
+	return &Writer{w: w}, nil
+}
+
+// WriteFrame appends one tick of input.
+func (rw *Writer) WriteFrame(f Frame) error {
+	var flag byte
+	if rw.have && f.Local == rw.prev.Local {
+		flag |= flagLocalRepeat
+	} else {
+		if f.Local.Up {
+			flag |= flagLocalUp
+		}
+		if f.Local.Down {
+			flag |= flagLocalDown
+		}
+	}
+	if rw.have && f.Remote == rw.prev.Remote {
+		flag |= flagRemoteRepeat
+	} else {
+		if f.Remote.Up {
+			flag |= flagRemoteUp
+		}
+		if f.Remote.Down {
+			flag |= flagRemoteDown
+		}
+	}
+	rw.prev = f
+	rw.have = true
+
+	_, err := rw.w.Write([]byte{flag})
+	return err
+}
+
+// Reader reads a Header followed by the Frames written by a Writer.
+type Reader struct {
+	r    io.Reader
+	prev Frame
+}
+
+// NewReader reads and validates the header from r, returning a Reader
+// positioned at the first frame.
+func NewReader(r io.Reader) (*Reader, Header, error) {
+	var h Header
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, Header{}, fmt.Errorf("replay: read header: %w", err)
+	}
+	if h.Version != formatVersion {
+		return nil, Header{}, fmt.Errorf("replay: unsupported file version %d, want %d", h.Version, formatVersion)
+	}
+	return &Reader{r: r}, h, nil
+}
+
+// ReadFrame returns the next recorded frame, or io.EOF once the file is
+// exhausted.
+func (rr *Reader) ReadFrame() (Frame, error) {
+	var buf [1]byte
+	if _, err := io.ReadFull(rr.r, buf[:]); err != nil {
+		return Frame{}, err
+	}
+	flag := buf[0]
+
+	f := rr.prev
+	if flag&flagLocalRepeat == 0 {
+		f.Local = Input{Up: flag&flagLocalUp != 0, Down: flag&flagLocalDown != 0}
+	}
+	if flag&flagRemoteRepeat == 0 {
+		f.Remote = Input{Up: flag&flagRemoteUp != 0, Down: flag&flagRemoteDown != 0}
+	}
+	rr.prev = f
+	return f, nil
+}
@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriterReaderRoundTrip(t *testing.T) {
+	want := []Frame{
+		{Local: Input{Up: true}, Remote: Input{Down: true}},
+		{Local: Input{Up: true}, Remote: Input{Down: true}}, // repeats both sides
+		{Local: Input{}, Remote: Input{Up: true}},
+		{Local: Input{Down: true}, Remote: Input{}},
+		{Local: Input{Down: true}, Remote: Input{}}, // repeats both sides again
+	}
+	header := Header{Seed: 0xC0FFEE, ConfigHash: 42, TickRate: 120, RemoteSpeed: 240}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, header)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, f := range want {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatalf("WriteFrame(%+v): %v", f, err)
+		}
+	}
+
+	r, gotHeader, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	header.Version = formatVersion // stamped by NewWriter
+	if gotHeader != header {
+		t.Fatalf("header round-trip: got %+v, want %+v", gotHeader, header)
+	}
+
+	for i, wantFrame := range want {
+		got, err := r.ReadFrame()
+		if err != nil {
+			t.Fatalf("ReadFrame(%d): %v", i, err)
+		}
+		if got != wantFrame {
+			t.Fatalf("frame %d: got %+v, want %+v", i, got, wantFrame)
+		}
+	}
+	if _, err := r.ReadFrame(); err != io.EOF {
+		t.Fatalf("ReadFrame past end: got err %v, want io.EOF", err)
+	}
+}
+
+func TestNewReaderRejectsWrongVersion(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewWriter(&buf, Header{}); err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	raw := buf.Bytes()
+	raw[0] = 0xFF // corrupt the version field
+
+	if _, _, err := NewReader(bytes.NewReader(raw)); err == nil {
+		t.Fatal("NewReader accepted a header with an unsupported version")
+	}
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"pingpong/internal/replay"
+	"pingpong/netplay"
+)
+
+// StartRecording begins writing every subsequent tick's input to path,
+// alongside the seed and config needed to reproduce the match later with
+// StartReplay. It must be called after a match has started (so g.seed is
+// set) and before the first tick runs.
+func (g *Game) StartRecording(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("replay: create %s: %w", path, err)
+	}
+	remoteSpeed := BasePaddleSpeed
+	if g.mode == ModeLocal {
+		remoteSpeed = aiSpeedForDifficulty(g.cfg.Difficulty)
+	}
+	w, err := replay.NewWriter(f, replay.Header{
+		Seed:        g.seed,
+		ConfigHash:  g.cfg.hash(),
+		TickRate:    physicsHz,
+		RemoteSpeed: remoteSpeed,
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	g.replayOut = w
+	g.replayOutFile = f
+	return nil
+}
+
+// StartReplay begins a match that plays back path instead of reading the
+// keyboard or the network. It mirrors startLocal/startNetwork: it seeds
+// randSrc and calls resetMatch itself.
+func (g *Game) StartReplay(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("replay: open %s: %w", path, err)
+	}
+	r, header, err := replay.NewReader(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if header.ConfigHash != g.cfg.hash() {
+		fmt.Fprintf(os.Stderr, "ping_pong: replay %s was recorded with different match settings; playback may desync\n", path)
+	}
+	if header.TickRate != physicsHz {
+		fmt.Fprintf(os.Stderr, "ping_pong: replay %s was recorded at %d Hz, simulation runs at %d Hz; playback may desync\n", path, header.TickRate, int(physicsHz))
+	}
+
+	g.mode = ModeReplay
+	g.replayIn = r
+	g.replayInFile = f
+	g.seed = header.Seed
+	g.replayRemoteSpeed = header.RemoteSpeed
+	g.randSrc = newPCGRand(g.seed, 0)
+	g.state = StatePlaying
+	g.resetMatch()
+	return nil
+}
+
+// closeReplay releases whichever replay files are open. Safe to call even
+// if neither was opened.
+func (g *Game) closeReplay() {
+	if g.replayOutFile != nil {
+		g.replayOutFile.Close()
+		g.replayOutFile = nil
+		g.replayOut = nil
+	}
+	if g.replayInFile != nil {
+		g.replayInFile.Close()
+		g.replayInFile = nil
+		g.replayIn = nil
+	}
+}
+
+// stepReplay advances one fixed-timestep frame by reading the next
+// recorded frame instead of the keyboard or netplay.Session. Reaching the
+// end of the file ends the match the same way a score-limit win does.
+func (g *Game) stepReplay() error {
+	f, err := g.replayIn.ReadFrame()
+	if err == io.EOF {
+		g.state = StateGameOver
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	localIn := netplay.Input{Up: f.Local.Up, Down: f.Local.Down}
+	remoteIn := netplay.Input{Up: f.Remote.Up, Down: f.Remote.Down}
+	g.stepPhysics(localIn, remoteIn, g.replayRemoteSpeed)
+	return nil
+}
+
+// recordFrame appends this tick's local/remote input to the in-progress
+// recording, if one is active.
+func (g *Game) recordFrame(local, remote netplay.Input) {
+	if g.replayOut == nil {
+		return
+	}
+	f := replay.Frame{
+		Local:  replay.Input{Up: local.Up, Down: local.Down},
+		Remote: replay.Input{Up: remote.Up, Down: remote.Down},
+	}
+	if err := g.replayOut.WriteFrame(f); err != nil {
+		fmt.Fprintf(os.Stderr, "ping_pong: replay recording failed, stopping: %v\n", err)
+		g.replayOutFile.Close()
+		g.replayOut = nil
+		g.replayOutFile = nil
+	}
+}
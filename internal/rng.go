@@ -0,0 +1,46 @@
+package internal
+
+// pcgRand is a small, deterministic PCG32-style generator. Unlike math/rand,
+// its output sequence depends only on its seed, which lets two network peers
+// (or a replay file) reproduce identical ball physics by sharing one seed.
+type pcgRand struct {
+	state uint64
+	inc   uint64
+}
+
+// newPCGRand seeds a generator the same way for every caller given the same
+// (seed, seq) pair, per the reference PCG32 initialization sequence.
+func newPCGRand(seed, seq uint64) *pcgRand {
+	r := &pcgRand{}
+	r.inc = (seq << 1) | 1
+	r.step()
+	r.state += seed
+	r.step()
+	return r
+}
+
+func (r *pcgRand) step() {
+	r.state = r.state*6364136223846793005 + r.inc
+}
+
+// Uint32 returns the next pseudo-random value in the sequence.
+func (r *pcgRand) Uint32() uint32 {
+	old := r.state
+	r.step()
+	xorshifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return (xorshifted >> rot) | (xorshifted << ((-rot) & 31))
+}
+
+// Float64 returns a pseudo-random value in [0, 1).
+func (r *pcgRand) Float64() float64 {
+	return float64(r.Uint32()) / float64(1<<32)
+}
+
+// Intn returns a pseudo-random value in [0, n).
+func (r *pcgRand) Intn(n int) int {
+	if n <= 0 {
+		panic("pcgRand: Intn called with n <= 0")
+	}
+	return int(r.Float64() * float64(n))
+}
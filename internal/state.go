@@ -0,0 +1,15 @@
+package internal
+
+// GameState is the game's top-level screen/phase. Menu, pause and resume
+// transitions happen only in response to edge-triggered input events (see
+// InputState); the simulation itself moves Playing to GameOver when a side
+// reaches the target score or a replay runs out of frames. No transition is
+// driven by elapsed wall-clock time.
+type GameState int
+
+const (
+	StateMenu GameState = iota
+	StatePlaying
+	StatePaused
+	StateGameOver
+)
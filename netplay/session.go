@@ -0,0 +1,271 @@
+// Package netplay provides the UDP transport and rollback input buffer used
+// to play Pong against a remote peer. It knows nothing about paddles or
+// balls: callers serialize their own simulation state into a GameState and
+// hand it to a Session, which is only responsible for getting local input to
+// the peer, collecting the peer's input, and telling the caller when and how
+// far back it needs to rewind.
+package netplay
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// bufferSize is how many recent frames of input/state we keep around so a
+// late-arriving remote input can still trigger a rollback and resimulate.
+const bufferSize = 8
+
+// Role identifies which side of the handshake a Session played.
+type Role int
+
+const (
+	RoleHost Role = iota
+	RoleJoin
+)
+
+// Input is one tick's worth of local or remote player intent. It must stay
+// small and stable across versions since it is what actually goes on the
+// wire every tick.
+type Input struct {
+	Up   bool
+	Down bool
+}
+
+// GameState is a serializable snapshot of everything the simulation needs to
+// resume deterministically from a given frame: paddle positions, ball
+// position/velocity, scores, and the RNG state at that frame.
+type GameState struct {
+	Frame       uint64
+	PlayerY     float64
+	AIY         float64
+	BallX       float64
+	BallY       float64
+	BallVX      float64
+	BallVY      float64
+	PlayerScore int
+	AIScore     int
+	RNGState    uint64
+}
+
+type frameSlot struct {
+	filled      bool
+	localIn     Input
+	remoteIn    Input
+	remoteFrame uint64
+	haveRemote  bool
+	state       GameState
+	haveState   bool
+
+	// appliedRemote is whatever remote input was actually simulated for
+	// this frame at the time (a prediction, or the real value if it had
+	// already arrived), so a later reconciliation pass can tell whether
+	// that specific frame's prediction turned out right.
+	appliedRemote Input
+	haveApplied   bool
+}
+
+// Session owns the UDP socket to a peer plus the rolling window of recent
+// input/state used for rollback. Callers drive it once per tick: record the
+// local input, pull whatever remote input has arrived, and ask whether a
+// rollback is needed before resimulating.
+type Session struct {
+	conn *net.UDPConn
+	peer *net.UDPAddr
+	role Role
+
+	startFrame uint64
+	seed       uint64
+
+	slots [bufferSize]frameSlot
+}
+
+// Handshake performs the host/join exchange that agrees on a shared start
+// frame and RNG seed before any gameplay input is exchanged. The host
+// chooses the seed; both sides start simulating at startFrame so the first
+// rollback window is already primed.
+func Handshake(role Role, localAddr, peerAddr string, seed uint64) (*Session, error) {
+	laddr, err := net.ResolveUDPAddr("udp", localAddr)
+	if err != nil {
+		return nil, fmt.Errorf("netplay: resolve local addr: %w", err)
+	}
+	raddr, err := net.ResolveUDPAddr("udp", peerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("netplay: resolve peer addr: %w", err)
+	}
+	conn, err := net.DialUDP("udp", laddr, raddr)
+	if err != nil {
+		return nil, fmt.Errorf("netplay: dial peer: %w", err)
+	}
+
+	s := &Session{conn: conn, peer: raddr, role: role}
+
+	type hello struct {
+		Seed       uint64
+		StartFrame uint64
+	}
+
+	switch role {
+	case RoleHost:
+		msg := hello{Seed: seed, StartFrame: bufferSize}
+		if err := s.send(msg); err != nil {
+			return nil, err
+		}
+		s.seed, s.startFrame = msg.Seed, msg.StartFrame
+	case RoleJoin:
+		var msg hello
+		if err := s.recv(&msg); err != nil {
+			return nil, err
+		}
+		s.seed, s.startFrame = msg.Seed, msg.StartFrame
+	}
+
+	return s, nil
+}
+
+func (s *Session) send(v interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return fmt.Errorf("netplay: encode: %w", err)
+	}
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+func (s *Session) recv(v interface{}) error {
+	buf := make([]byte, 4096)
+	n, err := s.conn.Read(buf)
+	if err != nil {
+		return fmt.Errorf("netplay: read: %w", err)
+	}
+	return gob.NewDecoder(bytes.NewReader(buf[:n])).Decode(v)
+}
+
+// Seed returns the RNG seed agreed on during the handshake.
+func (s *Session) Seed() uint64 { return s.seed }
+
+// StartFrame returns the first frame both peers begin simulating at.
+func (s *Session) StartFrame() uint64 { return s.startFrame }
+
+func (s *Session) slot(frame uint64) *frameSlot {
+	return &s.slots[frame%bufferSize]
+}
+
+// wireInput is what actually crosses the network: the frame it applies to
+// and the sender's input for that frame.
+type wireInput struct {
+	Frame uint64
+	In    Input
+}
+
+// SendLocalInput records the local player's input for frame and transmits it
+// to the peer. It does not block waiting for an ack.
+func (s *Session) SendLocalInput(frame uint64, in Input) error {
+	slot := s.slot(frame)
+	slot.localIn = in
+	slot.filled = true
+	return s.send(wireInput{Frame: frame, In: in})
+}
+
+// PollRemoteInput drains any remote input packets that have arrived without
+// blocking and files them into the buffer, keyed by frame.
+func (s *Session) PollRemoteInput() error {
+	if err := s.conn.SetReadDeadline(deadlineNow()); err != nil {
+		return err
+	}
+	for {
+		var msg wireInput
+		if err := s.recv(&msg); err != nil {
+			if isTimeout(err) {
+				return nil
+			}
+			return err
+		}
+		slot := s.slot(msg.Frame)
+		slot.remoteIn = msg.In
+		slot.remoteFrame = msg.Frame
+		slot.haveRemote = true
+	}
+}
+
+// RemoteInput returns the remote player's input for frame, if it has
+// arrived. The slot is keyed by frame%bufferSize and gets reused every
+// bufferSize frames, so this also checks remoteFrame matches frame (the
+// same pattern RewindTo uses via state.Frame) — without it, a slot whose
+// occupant from bufferSize frames ago never got overwritten (the peer's
+// packet for the current frame hasn't arrived yet) would be misread as a
+// confirmed input for the current frame instead of as "not arrived yet".
+func (s *Session) RemoteInput(frame uint64) (Input, bool) {
+	slot := s.slot(frame)
+	if !slot.haveRemote || slot.remoteFrame != frame {
+		return Input{}, false
+	}
+	return slot.remoteIn, true
+}
+
+// LocalInput returns whatever local input was recorded for frame via
+// SendLocalInput, if any. Used during rollback resimulation, where the
+// local side of history is already known and only the remote side needs
+// correcting.
+func (s *Session) LocalInput(frame uint64) (Input, bool) {
+	slot := s.slot(frame)
+	return slot.localIn, slot.filled
+}
+
+// SaveAppliedRemote records which remote input was actually simulated for
+// frame, whether that was a prediction or a confirmed value. Reconciliation
+// compares this against the value RemoteInput later confirms for the same
+// frame to tell whether a rollback is actually needed.
+func (s *Session) SaveAppliedRemote(frame uint64, in Input) {
+	slot := s.slot(frame)
+	slot.appliedRemote = in
+	slot.haveApplied = true
+}
+
+// AppliedRemoteInput returns whatever remote input was actually simulated
+// for frame, if SaveAppliedRemote has been called for it.
+func (s *Session) AppliedRemoteInput(frame uint64) (Input, bool) {
+	slot := s.slot(frame)
+	return slot.appliedRemote, slot.haveApplied
+}
+
+// SaveState stashes a snapshot of the simulation as it stands just before
+// frame is simulated, so a later rollback can restore it and re-run frame.
+func (s *Session) SaveState(frame uint64, state GameState) {
+	slot := s.slot(frame)
+	slot.state = state
+	slot.haveState = true
+}
+
+// RewindTo returns the snapshot saved for exactly frame, so the caller can
+// resimulate forward from there using corrected remote input. It reports
+// false if no snapshot was saved for frame or its slot has since been
+// reused by a later frame; earlier snapshots are not searched.
+func (s *Session) RewindTo(frame uint64) (GameState, bool) {
+	slot := s.slot(frame)
+	if !slot.haveState || slot.state.Frame != frame {
+		return GameState{}, false
+	}
+	return slot.state, true
+}
+
+// Close releases the underlying socket.
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// deadlineNow returns a read deadline one millisecond out, so a Read call
+// returns a queued packet at once and otherwise times out almost
+// immediately. A deadline already in the past would make every Read time
+// out, even with packets queued.
+func deadlineNow() time.Time {
+	return time.Now().Add(time.Millisecond)
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}